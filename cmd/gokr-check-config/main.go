@@ -0,0 +1,120 @@
+// gokr-check-config verifies that a kernel configuration satisfies one or more
+// lists of required options, failing if any option has a different value.
+//
+// The configuration can be read from a kernel image with embedded config (via
+// CONFIG_IKCONFIG, like the vmlinuz in this repository), a .config file or a
+// gzip-compressed .config file (like /proc/config.gz on a running device).
+//
+// Besides _build/required-options.txt, which every kernel build must satisfy,
+// _build/requirements contains lists for optional features (e.g. containers,
+// WireGuard, nftables):
+//
+//	gokr-check-config -requirements=_build/requirements/containers.txt vmlinuz
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gokrazy/kernel/internal/ikconfig"
+)
+
+func readConfig(fn string) (map[string]string, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := ikconfig.Load(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	config, err := ikconfig.Parse(bytes.NewReader(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	return config, nil
+}
+
+// check reports the options in the specified requirements file which config
+// does not satisfy and returns their number. A requirement can list
+// alternatives, e.g. CONFIG_VETH=y|m.
+func check(config map[string]string, requirements string, verbose bool) (violations int, _ error) {
+	required, err := ikconfig.ParseFile(requirements)
+	if err != nil {
+		return 0, err
+	}
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if verbose {
+		fmt.Printf("%s:\n", requirements)
+	}
+	for _, name := range names {
+		got, want := ikconfig.Value(config, name), required[name]
+		ok := ikconfig.Satisfied(config, name, want)
+		if !ok {
+			violations++
+		}
+		if verbose {
+			status := "ok"
+			if !ok {
+				status = "MISSING"
+			}
+			fmt.Printf("  %-40s %-8s (%s, want %s)\n", name, status, got, want)
+		} else if !ok {
+			log.Printf("%s: %s=%s, want %s=%s", requirements, name, got, name, want)
+		}
+	}
+	return violations, nil
+}
+
+func logic(requirements []string, kernel string, verbose bool) error {
+	config, err := readConfig(kernel)
+	if err != nil {
+		return err
+	}
+	var violations int
+	for _, fn := range requirements {
+		v, err := check(config, fn, verbose)
+		if err != nil {
+			return err
+		}
+		violations += v
+	}
+	if violations > 0 {
+		return fmt.Errorf("%s: %d required options not satisfied", kernel, violations)
+	}
+	log.Printf("%s: all required options satisfied", kernel)
+	return nil
+}
+
+func main() {
+	var (
+		requirements = flag.String("requirements",
+			"_build/required-options.txt",
+			"comma-separated list of files listing the required options, in .config format")
+
+		verbose = flag.Bool("v",
+			false,
+			"print the status of every required option, not just the missing ones")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [vmlinuz|.config|config.gz]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	kernel := "vmlinuz"
+	if flag.NArg() > 0 {
+		kernel = flag.Arg(0)
+	}
+	if err := logic(strings.Split(*requirements, ","), kernel, *verbose); err != nil {
+		log.Fatal(err)
+	}
+}
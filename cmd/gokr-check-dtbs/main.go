@@ -0,0 +1,203 @@
+// gokr-check-dtbs verifies that the device tree blobs in this repository are
+// well-formed, contain the nodes which booting gokrazy requires (e.g. /chosen
+// and an ethernet alias on boards with ethernet), and contain the nodes and
+// properties which our patches (see _build/series) add or change. Upstream DTS
+// churn can silently turn a patch into a no-op (or break it), so this check
+// runs after every kernel rebuild.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/gokrazy/kernel/internal/fdt"
+)
+
+type check struct {
+	// name identifies the check in error messages, e.g. the file name of
+	// the patch which this check covers.
+	name string
+
+	// pattern selects the .dtb files to check (see filepath.Match).
+	pattern string
+
+	fn func(root *fdt.Node) error
+}
+
+var checks = []check{
+	{
+		name:    "structure",
+		pattern: "*.dtb",
+		fn:      checkStructure,
+	},
+
+	{
+		name:    "ethernet",
+		pattern: "bcm271[01]-rpi-[34]*.dtb",
+		fn:      checkEthernet,
+	},
+
+	{
+		name:    "ethernet",
+		pattern: "bcm2711-rpi-cm4-io.dtb",
+		fn:      checkEthernet,
+	},
+
+	{
+		name:    "0201-enable-spidev.patch",
+		pattern: "*.dtb",
+		fn:      checkSpidev,
+	},
+
+	{
+		name:    "0001-Revert-add-index-to-the-ethernet-alias.patch",
+		pattern: "bcm2710-rpi-3-b.dtb",
+		fn:      checkEthernetAlias,
+	},
+}
+
+// checkStructure verifies the nodes which the Raspberry Pi firmware and the
+// kernel rely on, and that all aliases resolve.
+func checkStructure(root *fdt.Node) error {
+	for _, prop := range []string{"model", "compatible"} {
+		if _, ok := root.Props[prop]; !ok {
+			return fmt.Errorf("/: property %q not found", prop)
+		}
+	}
+	// The firmware fills in /chosen (bootargs) and /memory@0 (reg).
+	for _, path := range []string{"/chosen", "/memory@0", "/aliases"} {
+		if root.Lookup(path) == nil {
+			return fmt.Errorf("%s: node not found", path)
+		}
+	}
+	aliases := root.Lookup("/aliases")
+	for name := range aliases.Props {
+		target, _ := aliases.String(name)
+		if root.Lookup(target) == nil {
+			return fmt.Errorf("/aliases: %s points to non-existing node %q", name, target)
+		}
+	}
+	return nil
+}
+
+// checkEthernet verifies that the board’s ethernet controller can be found via
+// an alias (the firmware uses it to set local-mac-address) and is enabled.
+func checkEthernet(root *fdt.Node) error {
+	aliases := root.Lookup("/aliases")
+	if aliases == nil {
+		return fmt.Errorf("/aliases: node not found")
+	}
+	for _, name := range []string{"ethernet", "ethernet0"} {
+		target, ok := aliases.String(name)
+		if !ok {
+			continue
+		}
+		n := root.Lookup(target)
+		if n == nil {
+			return fmt.Errorf("/aliases: %s points to non-existing node %q", name, target)
+		}
+		if status, ok := n.String("status"); ok && status != "okay" && status != "ok" {
+			return fmt.Errorf("%s: status = %q, want %q", target, status, "okay")
+		}
+		return nil
+	}
+	return fmt.Errorf("/aliases: no ethernet alias found")
+}
+
+func checkSpidev(root *fdt.Node) error {
+	const spi = "/soc/spi@7e204000"
+	n := root.Lookup(spi)
+	if n == nil {
+		return fmt.Errorf("%s: node not found", spi)
+	}
+	if status, _ := n.String("status"); status != "okay" {
+		return fmt.Errorf("%s: status = %q, want %q", spi, status, "okay")
+	}
+	for _, prop := range []string{"dmas", "cs-gpios", "pinctrl-0"} {
+		if _, ok := n.Props[prop]; !ok {
+			return fmt.Errorf("%s: property %q not found", spi, prop)
+		}
+	}
+	for _, name := range []string{"spidev@0", "spidev@1"} {
+		c := n.Child(name)
+		if c == nil {
+			return fmt.Errorf("%s/%s: node not found", spi, name)
+		}
+		if got, want := c.Strings("compatible"), "brcm,bcm2835-spi"; !slices.Contains(got, want) {
+			return fmt.Errorf("%s/%s: compatible = %q, want %q", spi, name, got, want)
+		}
+	}
+	for _, path := range []string{
+		"/soc/gpio@7e200000/spi0_pins",
+		"/soc/gpio@7e200000/spi0_cs_pins",
+	} {
+		if root.Lookup(path) == nil {
+			return fmt.Errorf("%s: node not found", path)
+		}
+	}
+	return nil
+}
+
+func checkEthernetAlias(root *fdt.Node) error {
+	aliases := root.Lookup("/aliases")
+	if aliases == nil {
+		return fmt.Errorf("/aliases: node not found")
+	}
+	if _, ok := aliases.Props["ethernet"]; !ok {
+		return fmt.Errorf("/aliases: ethernet alias not found (the firmware only sets local-mac-address for an ethernet alias without index)")
+	}
+	if _, ok := aliases.Props["ethernet0"]; ok {
+		return fmt.Errorf("/aliases: unexpected ethernet0 alias")
+	}
+	return nil
+}
+
+func logic(root string) error {
+	dtbs, err := filepath.Glob(filepath.Join(root, "*.dtb"))
+	if err != nil {
+		return err
+	}
+	if len(dtbs) == 0 {
+		return fmt.Errorf("no .dtb files found in %s", root)
+	}
+	var failed int
+	for _, fn := range dtbs {
+		b, err := os.ReadFile(fn)
+		if err != nil {
+			return err
+		}
+		tree, err := fdt.Parse(b)
+		if err != nil {
+			return fmt.Errorf("%s: %v", fn, err)
+		}
+		base := filepath.Base(fn)
+		for _, c := range checks {
+			if ok, _ := filepath.Match(c.pattern, base); !ok {
+				continue
+			}
+			if err := c.fn(tree); err != nil {
+				log.Printf("%s: %s: %v", base, c.name, err)
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	log.Printf("all checks passed for %d device tree blobs", len(dtbs))
+	return nil
+}
+
+func main() {
+	root := flag.String("root",
+		".",
+		"path to the kernel repository checkout (containing the .dtb files)")
+	flag.Parse()
+	if err := logic(*root); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gokrazy/kernel/internal/fdt"
+)
+
+func TestCommittedDTBs(t *testing.T) {
+	if err := logic("../.."); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSpidevMissing verifies that checkSpidev notices when the spidev patch
+// no longer applies, e.g. because upstream renamed the spi node.
+func TestSpidevMissing(t *testing.T) {
+	b, err := os.ReadFile("../../bcm2710-rpi-3-b.dtb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := fdt.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spi := root.Lookup("/soc/spi@7e204000")
+	spi.Children = nil
+	if err := checkSpidev(root); err == nil {
+		t.Errorf("checkSpidev() = nil, want error for missing spidev nodes")
+	}
+	spi.Props["status"] = []byte("disabled\x00")
+	if err := checkSpidev(root); err == nil {
+		t.Errorf("checkSpidev() = nil, want error for disabled spi node")
+	}
+}
@@ -0,0 +1,176 @@
+// gokr-check-size verifies that vmlinuz and the modules tree stay within the
+// size budgets declared in _build/size-budget.txt. When the modules tree is
+// over budget, the biggest contributors (per directory) are printed.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gokrazy/kernel/internal/modules"
+)
+
+// breakdownDepth is the number of path components by which module sizes are
+// grouped, e.g. kernel/drivers/net.
+const breakdownDepth = 3
+
+// artifacts are the artifacts which can have a size budget, in check order.
+var artifacts = []string{"vmlinuz", "lib/modules"}
+
+func readBudget(fn string) (map[string]int64, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	budget := make(map[string]int64)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s: malformed line: %q", fn, line)
+		}
+		if !slices.Contains(artifacts, fields[0]) {
+			return nil, fmt.Errorf("%s: unknown artifact %q", fn, fields[0])
+		}
+		limit, err := strconv.ParseInt(fields[1], 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn, err)
+		}
+		budget[fields[0]] = limit
+	}
+	return budget, scanner.Err()
+}
+
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
+
+// printBreakdown logs the module sizes in dir, grouped by directory and
+// sorted by size.
+func printBreakdown(dir string) error {
+	sizes, err := modules.Sizes(dir)
+	if err != nil {
+		return err
+	}
+	byDir := make(map[string]int64)
+	for p, size := range sizes {
+		components := strings.Split(path.Dir(p), "/")
+		if len(components) > breakdownDepth {
+			components = components[:breakdownDepth]
+		}
+		byDir[strings.Join(components, "/")] += size
+	}
+	dirs := make([]string, 0, len(byDir))
+	for d := range byDir {
+		dirs = append(dirs, d)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if byDir[dirs[i]] != byDir[dirs[j]] {
+			return byDir[dirs[i]] > byDir[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+	if len(dirs) > 10 {
+		dirs = dirs[:10]
+	}
+	log.Printf("biggest contributors:")
+	for _, d := range dirs {
+		log.Printf("  %10d  %s", byDir[d], d)
+	}
+	return nil
+}
+
+func logic(root, budgetPath string) error {
+	budget, err := readBudget(budgetPath)
+	if err != nil {
+		return err
+	}
+	var exceeded int
+	for _, artifact := range artifacts {
+		limit, ok := budget[artifact]
+		if !ok {
+			continue
+		}
+		var (
+			size int64
+			dir  string
+		)
+		if artifact == "lib/modules" {
+			dir, err = modules.Dir(root)
+			if err != nil {
+				return err
+			}
+			size, err = dirSize(dir)
+		} else {
+			var st os.FileInfo
+			st, err = os.Stat(filepath.Join(root, artifact))
+			if err == nil {
+				size = st.Size()
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if size <= limit {
+			log.Printf("%s: %d bytes (budget: %d bytes, %.1f%% used)", artifact, size, limit, 100*float64(size)/float64(limit))
+			continue
+		}
+		exceeded++
+		log.Printf("%s: %d bytes exceeds budget of %d bytes by %d bytes", artifact, size, limit, size-limit)
+		if dir != "" {
+			if err := printBreakdown(dir); err != nil {
+				return err
+			}
+		}
+	}
+	if exceeded > 0 {
+		return fmt.Errorf("%d size budgets exceeded (see %s)", exceeded, budgetPath)
+	}
+	return nil
+}
+
+func main() {
+	var (
+		root = flag.String("root",
+			".",
+			"path to the kernel repository checkout")
+
+		budget = flag.String("budget",
+			"_build/size-budget.txt",
+			"path to the size budget file")
+	)
+	flag.Parse()
+	if err := logic(*root, *budget); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, fn string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fn, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOverBudget(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "vmlinuz"), 100)
+	mods := filepath.Join(root, "lib", "modules", "6.12.9")
+	writeFile(t, filepath.Join(mods, "kernel", "drivers", "net", "wireless", "big.ko"), 3000)
+	writeFile(t, filepath.Join(mods, "kernel", "fs", "small.ko"), 200)
+	budget := filepath.Join(root, "size-budget.txt")
+	if err := os.WriteFile(budget, []byte("vmlinuz 1000\nlib/modules 2000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	err := logic(root, budget)
+	if err == nil {
+		t.Fatalf("logic() = nil, want error for exceeded budget")
+	}
+	if want := "1 size budgets exceeded"; !strings.Contains(err.Error(), want) {
+		t.Errorf("logic() = %v, want error containing %q", err, want)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"lib/modules: 3200 bytes exceeds budget of 2000 bytes",
+		"biggest contributors",
+		"3000  kernel/drivers/net",
+		"200  kernel/fs",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	// Within budget:
+	if err := os.WriteFile(budget, []byte("vmlinuz 1000\nlib/modules 4000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := logic(root, budget); err != nil {
+		t.Errorf("logic() = %v, want nil", err)
+	}
+}
+
+func TestReadBudget(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		budget  string
+		wantErr string
+	}{
+		{
+			desc:   "valid",
+			budget: "# comment\n\nvmlinuz 67108864\nlib/modules 0x1000000\n",
+		},
+
+		{
+			desc:    "unknown artifact",
+			budget:  "initramfs 1000\n",
+			wantErr: `unknown artifact "initramfs"`,
+		},
+
+		{
+			desc:    "missing size",
+			budget:  "vmlinuz\n",
+			wantErr: "malformed line",
+		},
+
+		{
+			desc:    "too many fields",
+			budget:  "vmlinuz 1000 bytes\n",
+			wantErr: "malformed line",
+		},
+
+		{
+			desc:    "invalid size",
+			budget:  "vmlinuz 64MiB\n",
+			wantErr: "invalid syntax",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			fn := filepath.Join(t.TempDir(), "size-budget.txt")
+			if err := os.WriteFile(fn, []byte(tt.budget), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := readBudget(fn)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("readBudget() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// gokr-config-diff compares the configuration of the kernel running on a
+// gokrazy device (/proc/config.gz) with the configuration embedded in the
+// vmlinuz of this repository, answering “is my device running the kernel I
+// think it is?”.
+//
+// The device config is fetched via SSH (e.g. github.com/gokrazy/breakglass
+// with a cat binary, e.g. from github.com/gokrazy/serial-busybox, installed):
+//
+//	gokr-config-diff -device=gokrazy
+//
+// Alternatively, a previously copied config.gz can be specified with -config.
+//
+// The output uses the format of the kernel’s scripts/diffconfig. Like
+// diff(1), the exit status is 0 if the configurations match, 1 if they differ
+// and 2 if an error occurred (e.g. the device could not be reached via SSH).
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/gokrazy/kernel/internal/ikconfig"
+)
+
+func fetchDeviceConfig(ssh, device string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(ssh, device, "cat", "/proc/config.gz")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v (stderr: %s)", cmd.Args, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, nil
+}
+
+func parse(name string, b []byte) (map[string]string, error) {
+	cfg, err := ikconfig.Load(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	config, err := ikconfig.Parse(bytes.NewReader(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return config, nil
+}
+
+// diff prints the differences between the from and to configuration to w,
+// like scripts/diffconfig: removed options are prefixed with -, added options
+// with +, and changed options are printed as “ CONFIG_X y -> m”. It returns
+// the number of differences.
+func diff(w io.Writer, from, to map[string]string) int {
+	names := make(map[string]bool)
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	var differences int
+	for _, name := range sorted {
+		o, inFrom := from[name]
+		n, inTo := to[name]
+		switch {
+		case inFrom && !inTo:
+			fmt.Fprintf(w, "-%s %s\n", name, o)
+		case !inFrom && inTo:
+			fmt.Fprintf(w, "+%s %s\n", name, n)
+		case o != n:
+			fmt.Fprintf(w, " %s %s -> %s\n", name, o, n)
+		default:
+			continue
+		}
+		differences++
+	}
+	return differences
+}
+
+func logic(ssh, device, configPath, kernel string) (int, error) {
+	var (
+		deviceName string
+		deviceRaw  []byte
+		err        error
+	)
+	switch {
+	case device != "" && configPath != "":
+		return 0, fmt.Errorf("-device and -config are mutually exclusive")
+	case device != "":
+		deviceName = device + ":/proc/config.gz"
+		deviceRaw, err = fetchDeviceConfig(ssh, device)
+	case configPath != "":
+		deviceName = configPath
+		deviceRaw, err = os.ReadFile(configPath)
+	default:
+		return 0, fmt.Errorf("either -device or -config must be specified")
+	}
+	if err != nil {
+		return 0, err
+	}
+	deviceConfig, err := parse(deviceName, deviceRaw)
+	if err != nil {
+		return 0, err
+	}
+
+	kernelRaw, err := os.ReadFile(kernel)
+	if err != nil {
+		return 0, err
+	}
+	kernelConfig, err := parse(kernel, kernelRaw)
+	if err != nil {
+		return 0, err
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", kernel, deviceName)
+	differences := diff(os.Stdout, kernelConfig, deviceConfig)
+	if differences == 0 {
+		log.Printf("%s matches %s", deviceName, kernel)
+	}
+	return differences, nil
+}
+
+func main() {
+	var (
+		device = flag.String("device",
+			"",
+			"SSH destination (e.g. the gokrazy hostname) to fetch /proc/config.gz from")
+
+		config = flag.String("config",
+			"",
+			"path to a config file (config.gz or .config) copied from the device")
+
+		ssh = flag.String("ssh",
+			"ssh",
+			"ssh client to use for -device")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [vmlinuz]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	kernel := "vmlinuz"
+	if flag.NArg() > 0 {
+		kernel = flag.Arg(0)
+	}
+	differences, err := logic(*ssh, *device, *config, kernel)
+	if err != nil {
+		// Not log.Fatal: its exit status 1 means “configurations differ”.
+		log.Print(err)
+		os.Exit(2)
+	}
+	if differences > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		from, to map[string]string
+		want     string
+	}{
+		{
+			desc: "identical",
+			from: map[string]string{"CONFIG_TUN": "y"},
+			to:   map[string]string{"CONFIG_TUN": "y"},
+			want: "",
+		},
+
+		{
+			desc: "added",
+			from: map[string]string{},
+			to:   map[string]string{"CONFIG_TUN": "y"},
+			want: "+CONFIG_TUN y\n",
+		},
+
+		{
+			desc: "removed",
+			from: map[string]string{"CONFIG_TUN": "y"},
+			to:   map[string]string{},
+			want: "-CONFIG_TUN y\n",
+		},
+
+		{
+			desc: "changed",
+			from: map[string]string{"CONFIG_TUN": "y"},
+			to:   map[string]string{"CONFIG_TUN": "m"},
+			want: " CONFIG_TUN y -> m\n",
+		},
+
+		{
+			desc: "sorted",
+			from: map[string]string{
+				"CONFIG_HZ":    "250",
+				"CONFIG_KASAN": "n",
+				"CONFIG_TUN":   "y",
+			},
+			to: map[string]string{
+				"CONFIG_HZ":   "1000",
+				"CONFIG_TUN":  "y",
+				"CONFIG_VETH": "m",
+			},
+			want: " CONFIG_HZ 250 -> 1000\n" +
+				"-CONFIG_KASAN n\n" +
+				"+CONFIG_VETH m\n",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			differences := diff(&buf, tt.from, tt.to)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("diff() printed %q, want %q", got, tt.want)
+			}
+			if got, want := differences, bytes.Count([]byte(tt.want), []byte("\n")); got != want {
+				t.Errorf("diff() = %d, want %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// gokr-kernel-info prints what a kernel image is, without booting it: the
+// version banner (release, builder, toolchain), hashes of the image
+// and its embedded config, notable config options and which of the optional
+// features listed in _build/requirements it supports.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gokrazy/kernel/internal/buildinfo"
+	"github.com/gokrazy/kernel/internal/ikconfig"
+)
+
+var notable = []struct {
+	label  string
+	option string
+}{
+	{"timer frequency (HZ)", "CONFIG_HZ"},
+	{"4K pages", "CONFIG_ARM64_4K_PAGES"},
+	{"16K pages", "CONFIG_ARM64_16K_PAGES"},
+	{"64K pages", "CONFIG_ARM64_64K_PAGES"},
+	{"loadable modules", "CONFIG_MODULES"},
+	{"module compression", "CONFIG_MODULE_COMPRESS"},
+	{"module signing", "CONFIG_MODULE_SIG"},
+	{"BTF (for eBPF CO-RE)", "CONFIG_DEBUG_INFO_BTF"},
+	{"Landlock", "CONFIG_SECURITY_LANDLOCK"},
+	{"KFENCE", "CONFIG_KFENCE"},
+	{"KASAN", "CONFIG_KASAN"},
+}
+
+func preemption(config map[string]string) string {
+	for _, p := range []struct {
+		option string
+		model  string
+	}{
+		{"CONFIG_PREEMPT_RT", "real-time (PREEMPT_RT)"},
+		{"CONFIG_PREEMPT", "low-latency desktop (PREEMPT)"},
+		{"CONFIG_PREEMPT_VOLUNTARY", "voluntary (PREEMPT_VOLUNTARY)"},
+	} {
+		if ikconfig.Value(config, p.option) == "y" {
+			return p.model
+		}
+	}
+	return "none (PREEMPT_NONE)"
+}
+
+func humanValue(v string) string {
+	switch v {
+	case "y":
+		return "yes"
+	case "m":
+		return "module"
+	case "n":
+		return "no"
+	}
+	return v
+}
+
+func logic(kernel, requirementsDir string) error {
+	image, err := os.ReadFile(kernel)
+	if err != nil {
+		return err
+	}
+	var info buildinfo.Info
+	if err := buildinfo.ParseBanner(image, &info); err != nil {
+		return fmt.Errorf("%s: %v", kernel, err)
+	}
+	cfg, err := ikconfig.Extract(image)
+	if err != nil {
+		return fmt.Errorf("%s: %v", kernel, err)
+	}
+	config, err := ikconfig.Parse(bytes.NewReader(cfg))
+	if err != nil {
+		return fmt.Errorf("%s: %v", kernel, err)
+	}
+
+	fmt.Printf("%s:\n", kernel)
+	fmt.Printf("  release:    %s\n", info.Release)
+	fmt.Printf("  version:    %s\n", info.Version)
+	fmt.Printf("  built by:   %s\n", info.Builder)
+	if info.BannerTimestamp != nil {
+		fmt.Printf("  timestamp:  %s (from the banner, fixed for reproducible builds: not the build time)\n", info.BannerTimestamp.Format("2006-01-02 15:04:05 MST"))
+	}
+	fmt.Printf("  toolchain:  %s\n", info.Toolchain)
+	fmt.Printf("  image:      sha256:%s\n", buildinfo.SHA256(image))
+	fmt.Printf("  config:     sha256:%s (%d options)\n", buildinfo.SHA256(cfg), len(config))
+
+	fmt.Printf("\nnotable options:\n")
+	fmt.Printf("  %-24s %s\n", "preemption", preemption(config))
+	for _, n := range notable {
+		fmt.Printf("  %-24s %s\n", n.label, humanValue(ikconfig.Value(config, n.option)))
+	}
+
+	requirements, err := filepath.Glob(filepath.Join(requirementsDir, "*.txt"))
+	if err != nil {
+		return err
+	}
+	if len(requirements) == 0 {
+		return nil
+	}
+	sort.Strings(requirements)
+	fmt.Printf("\nfeatures (see %s):\n", requirementsDir)
+	for _, fn := range requirements {
+		required, err := ikconfig.ParseFile(fn)
+		if err != nil {
+			return err
+		}
+		var missing []string
+		for name, want := range required {
+			if !ikconfig.Satisfied(config, name, want) {
+				missing = append(missing, name)
+			}
+		}
+		sort.Strings(missing)
+		status := "yes"
+		if len(missing) > 0 {
+			status = "no, missing " + strings.Join(missing, ", ")
+		}
+		feature := strings.TrimSuffix(filepath.Base(fn), ".txt")
+		fmt.Printf("  %-24s %s\n", feature, status)
+	}
+	return nil
+}
+
+func main() {
+	requirementsDir := flag.String("requirements_dir",
+		"_build/requirements",
+		"directory containing feature requirement lists (*.txt, in .config format)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [vmlinuz]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	kernel := "vmlinuz"
+	if flag.NArg() > 0 {
+		kernel = flag.Arg(0)
+	}
+	if err := logic(kernel, *requirementsDir); err != nil {
+		log.Fatal(err)
+	}
+}
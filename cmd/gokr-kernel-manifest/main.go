@@ -0,0 +1,46 @@
+// gokr-kernel-manifest writes a machine-readable description of the kernel in
+// this repository (kernel.json) next to vmlinuz: kernel version, source URL,
+// applied patches and hashes of the image and its configuration. gokrazy
+// tooling can use it to show which kernel a device is running.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/gokrazy/kernel/internal/buildinfo"
+)
+
+func logic(root, output string) error {
+	info, err := buildinfo.Read(root)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, append(b, '\n'), 0644); err != nil {
+		return err
+	}
+	log.Printf("wrote %s for Linux %s", output, info.Release)
+	return nil
+}
+
+func main() {
+	var (
+		root = flag.String("root",
+			".",
+			"path to the kernel repository checkout")
+
+		output = flag.String("output",
+			"kernel.json",
+			"path to write the manifest to")
+	)
+	flag.Parse()
+	if err := logic(*root, *output); err != nil {
+		log.Fatal(err)
+	}
+}
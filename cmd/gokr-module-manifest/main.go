@@ -0,0 +1,138 @@
+// gokr-module-manifest writes a JSON manifest (_build/modules.manifest.json)
+// describing each kernel module in lib/modules: its description, license,
+// firmware files and parameters, as reported by modinfo(8). This documents what
+// a release ships and lets tooling flag modules which need firmware blobs.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gokrazy/kernel/internal/modules"
+)
+
+type param struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type module struct {
+	Name        string   `json:"name"`
+	Path        string   `json:"path"`
+	Description string   `json:"description,omitempty"`
+	License     string   `json:"license,omitempty"`
+	Firmware    []string `json:"firmware,omitempty"`
+	Params      []param  `json:"params,omitempty"`
+}
+
+type manifest struct {
+	Release string   `json:"release"`
+	Modules []module `json:"modules"`
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// params combines the parm (name:description) and parmtype (name:type)
+// entries of a module.
+func params(info map[string][]string) []param {
+	byName := make(map[string]*param)
+	get := func(name string) *param {
+		if p, ok := byName[name]; ok {
+			return p
+		}
+		p := &param{Name: name}
+		byName[name] = p
+		return p
+	}
+	for _, v := range info["parm"] {
+		name, desc, _ := strings.Cut(v, ":")
+		get(name).Description = desc
+	}
+	for _, v := range info["parmtype"] {
+		name, typ, _ := strings.Cut(v, ":")
+		get(name).Type = typ
+	}
+	result := make([]param, 0, len(byName))
+	for _, p := range byName {
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func logic(root, output string) error {
+	dir, err := modules.Dir(root)
+	if err != nil {
+		return err
+	}
+	sizes, err := modules.Sizes(dir)
+	if err != nil {
+		return err
+	}
+	m := manifest{Release: filepath.Base(dir)}
+	var firmware int
+	for path := range sizes {
+		info, err := modules.ReadModinfo(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil {
+			return err
+		}
+		name := first(info["name"])
+		if name == "" {
+			name = modules.Name(path)
+		}
+		mod := module{
+			Name:        name,
+			Path:        path,
+			Description: first(info["description"]),
+			License:     first(info["license"]),
+			Firmware:    info["firmware"],
+			Params:      params(info),
+		}
+		if len(mod.Firmware) > 0 {
+			firmware++
+		}
+		m.Modules = append(m.Modules, mod)
+	}
+	sort.Slice(m.Modules, func(i, j int) bool {
+		return m.Modules[i].Name < m.Modules[j].Name
+	})
+
+	b, err := json.MarshalIndent(&m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, append(b, '\n'), 0644); err != nil {
+		return err
+	}
+	log.Printf("wrote %s (%d modules, %d referencing firmware)", output, len(m.Modules), firmware)
+	return nil
+}
+
+func main() {
+	var (
+		root = flag.String("root",
+			".",
+			"path to the kernel repository checkout (containing lib/modules)")
+
+		output = flag.String("output",
+			"_build/modules.manifest.json",
+			"path to write the manifest to")
+	)
+	flag.Parse()
+	if err := logic(*root, *output); err != nil {
+		log.Fatal(err)
+	}
+}
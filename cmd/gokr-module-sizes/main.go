@@ -0,0 +1,166 @@
+// gokr-module-sizes writes a report of kernel module sizes (sorted by size,
+// with deltas against the report committed in a base git revision, see -base)
+// to _build/modules.size, so that reviewers of config changes can see what
+// each new driver costs.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gokrazy/kernel/internal/modules"
+)
+
+// parseReport parses a report previously written by writeReport.
+func parseReport(b []byte) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed line: %q", line)
+		}
+		size, err := strconv.ParseInt(fields[0], 0, 64)
+		if err != nil {
+			return nil, err
+		}
+		sizes[fields[2]] = size
+	}
+	return sizes, scanner.Err()
+}
+
+// committedReport is the path of the report in the kernel repository.
+const committedReport = "_build/modules.size"
+
+// readBaseReport returns the report as committed in the specified git
+// revision of the kernel repository checkout at root. Reading the committed
+// version (instead of the file on disk) keeps the deltas stable when
+// gokr-module-sizes is run more than once. A report which does not exist in
+// that revision is treated as empty.
+func readBaseReport(root, rev string) (map[string]int64, error) {
+	git := func(args ...string) *exec.Cmd {
+		return exec.Command("git", append([]string{"-C", root}, args...)...)
+	}
+	if err := git("rev-parse", "--quiet", "--verify", rev+"^{commit}").Run(); err != nil {
+		return nil, fmt.Errorf("git revision %q not found in %s: %v", rev, root, err)
+	}
+	// ./ makes the path relative to root, not to the top of the repository.
+	object := rev + ":./" + committedReport
+	if err := git("cat-file", "-e", object).Run(); err != nil {
+		log.Printf("%s does not exist in %s, treating all modules as new", committedReport, rev)
+		return make(map[string]int64), nil
+	}
+	show := git("show", object)
+	show.Stderr = os.Stderr
+	b, err := show.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", show.Args, err)
+	}
+	sizes, err := parseReport(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s (%s): %v", committedReport, rev, err)
+	}
+	return sizes, nil
+}
+
+func delta(cur, prev int64) string {
+	d := cur - prev
+	if d > 0 {
+		return "+" + strconv.FormatInt(d, 10)
+	}
+	return strconv.FormatInt(d, 10)
+}
+
+func writeReport(fn, release string, cur, prev map[string]int64) error {
+	paths := make([]string, 0, len(cur))
+	var total, prevTotal int64
+	for path, size := range cur {
+		paths = append(paths, path)
+		total += size
+	}
+	for _, size := range prev {
+		prevTotal += size
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if cur[paths[i]] != cur[paths[j]] {
+			return cur[paths[i]] > cur[paths[j]]
+		}
+		return paths[i] < paths[j]
+	})
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# kernel module sizes for Linux %s, generated by gokr-module-sizes\n", release)
+	fmt.Fprintf(&buf, "# total: %d bytes (%s) in %d modules\n", total, delta(total, prevTotal), len(cur))
+	fmt.Fprintf(&buf, "# size\tdelta\tpath\n")
+	for _, path := range paths {
+		d := "new"
+		if p, ok := prev[path]; ok {
+			d = delta(cur[path], p)
+		}
+		fmt.Fprintf(&buf, "%d\t%s\t%s\n", cur[path], d, path)
+	}
+	var removed []string
+	for path := range prev {
+		if _, ok := cur[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(removed)
+	for _, path := range removed {
+		fmt.Fprintf(&buf, "# removed: %s (%d bytes)\n", path, prev[path])
+	}
+	return os.WriteFile(fn, buf.Bytes(), 0644)
+}
+
+func logic(root, report, base string) error {
+	dir, err := modules.Dir(root)
+	if err != nil {
+		return err
+	}
+	cur, err := modules.Sizes(dir)
+	if err != nil {
+		return err
+	}
+	prev, err := readBaseReport(root, base)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(report, filepath.Base(dir), cur, prev); err != nil {
+		return err
+	}
+	log.Printf("wrote %s (%d modules)", report, len(cur))
+	return nil
+}
+
+func main() {
+	var (
+		root = flag.String("root",
+			".",
+			"path to the kernel repository checkout (containing lib/modules)")
+
+		report = flag.String("report",
+			committedReport,
+			"path to write the report to")
+
+		base = flag.String("base",
+			"HEAD",
+			"git revision (of the -root checkout) whose "+committedReport+" the deltas are computed against")
+	)
+	flag.Parse()
+	if err := logic(*root, *report, *base); err != nil {
+		log.Fatal(err)
+	}
+}
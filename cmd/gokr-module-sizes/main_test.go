@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteReport(t *testing.T) {
+	prev := map[string]int64{
+		"kernel/grown.ko":   1000,
+		"kernel/shrunk.ko":  3000,
+		"kernel/same.ko":    500,
+		"kernel/removed.ko": 700,
+	}
+	cur := map[string]int64{
+		"kernel/grown.ko":  1200,
+		"kernel/shrunk.ko": 2900,
+		"kernel/same.ko":   500,
+		"kernel/added.ko":  100,
+	}
+	fn := filepath.Join(t.TempDir(), "modules.size")
+	if err := writeReport(fn, "6.12.9", cur, prev); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{
+		"# total: 4700 bytes (-500) in 4 modules\n",
+		"2900\t-100\tkernel/shrunk.ko\n",
+		"1200\t+200\tkernel/grown.ko\n",
+		"500\t0\tkernel/same.ko\n",
+		"100\tnew\tkernel/added.ko\n",
+		"# removed: kernel/removed.ko (700 bytes)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report does not contain %q:\n%s", want, got)
+		}
+	}
+
+	// The report must round-trip, so that it can serve as the base of the
+	// next report: removed modules (comments) must not be parsed as sizes.
+	parsed, err := parseReport(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, cur) {
+		t.Errorf("parseReport() = %v, want %v", parsed, cur)
+	}
+}
+
+func TestParseReportMalformed(t *testing.T) {
+	for _, report := range []string{
+		"123\tkernel/missing-delta.ko\n",
+		"abc\t0\tkernel/size-not-a-number.ko\n",
+	} {
+		if got, err := parseReport([]byte(report)); err == nil {
+			t.Errorf("parseReport(%q) = %v, want error", report, got)
+		}
+	}
+}
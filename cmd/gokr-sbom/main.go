@@ -0,0 +1,195 @@
+// gokr-sbom writes an SPDX 2.3 software bill of materials (kernel.spdx.json)
+// for the kernel in this repository, listing the upstream kernel version and
+// source, the applied patches (with hashes) and the toolchain which built
+// vmlinuz.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gokrazy/kernel/internal/buildinfo"
+)
+
+type checksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type externalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type pkg struct {
+	Name             string        `json:"name"`
+	SPDXID           string        `json:"SPDXID"`
+	VersionInfo      string        `json:"versionInfo,omitempty"`
+	DownloadLocation string        `json:"downloadLocation"`
+	FilesAnalyzed    bool          `json:"filesAnalyzed"`
+	Checksums        []checksum    `json:"checksums,omitempty"`
+	LicenseConcluded string        `json:"licenseConcluded"`
+	LicenseDeclared  string        `json:"licenseDeclared"`
+	CopyrightText    string        `json:"copyrightText"`
+	Comment          string        `json:"comment,omitempty"`
+	ExternalRefs     []externalRef `json:"externalRefs,omitempty"`
+}
+
+type file struct {
+	FileName         string     `json:"fileName"`
+	SPDXID           string     `json:"SPDXID"`
+	Checksums        []checksum `json:"checksums"`
+	LicenseConcluded string     `json:"licenseConcluded"`
+	CopyrightText    string     `json:"copyrightText"`
+}
+
+type relationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+type creationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type document struct {
+	SPDXVersion       string         `json:"spdxVersion"`
+	DataLicense       string         `json:"dataLicense"`
+	SPDXID            string         `json:"SPDXID"`
+	Name              string         `json:"name"`
+	DocumentNamespace string         `json:"documentNamespace"`
+	CreationInfo      creationInfo   `json:"creationInfo"`
+	Packages          []pkg          `json:"packages"`
+	Files             []file         `json:"files,omitempty"`
+	Relationships     []relationship `json:"relationships"`
+}
+
+func sbom(info *buildinfo.Info, created time.Time) *document {
+	const (
+		linuxID   = "SPDXRef-Package-linux"
+		vmlinuzID = "SPDXRef-Package-vmlinuz"
+	)
+	doc := &document{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        "gokrazy-kernel-" + info.Release,
+		DocumentNamespace: fmt.Sprintf("https://github.com/gokrazy/kernel/spdx/%s-%s",
+			info.Release, info.ImageSHA256[:12]),
+		CreationInfo: creationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: gokr-sbom"},
+		},
+		Packages: []pkg{
+			{
+				Name:             "linux",
+				SPDXID:           linuxID,
+				VersionInfo:      info.Release,
+				DownloadLocation: info.SourceURL,
+				LicenseConcluded: "GPL-2.0-only",
+				LicenseDeclared:  "GPL-2.0-only WITH Linux-syscall-note",
+				CopyrightText:    "NOASSERTION",
+				ExternalRefs: []externalRef{
+					{
+						ReferenceCategory: "SECURITY",
+						ReferenceType:     "cpe23Type",
+						ReferenceLocator:  "cpe:2.3:o:linux:linux_kernel:" + info.Release + ":*:*:*:*:*:*:*",
+					},
+				},
+			},
+
+			{
+				Name:             "vmlinuz",
+				SPDXID:           vmlinuzID,
+				VersionInfo:      info.Release,
+				DownloadLocation: "git+https://github.com/gokrazy/kernel",
+				Checksums: []checksum{
+					{Algorithm: "SHA256", ChecksumValue: info.ImageSHA256},
+				},
+				LicenseConcluded: "GPL-2.0-only",
+				LicenseDeclared:  "GPL-2.0-only",
+				CopyrightText:    "NOASSERTION",
+				Comment: fmt.Sprintf("built by %s using %s, embedded .config SHA256 %s",
+					info.Builder, info.Toolchain, info.ConfigSHA256),
+			},
+		},
+		Relationships: []relationship{
+			{"SPDXRef-DOCUMENT", "DESCRIBES", vmlinuzID},
+			{vmlinuzID, "GENERATED_FROM", linuxID},
+		},
+	}
+	for i, p := range info.Patches {
+		id := fmt.Sprintf("SPDXRef-File-patch-%d", i+1)
+		doc.Files = append(doc.Files, file{
+			FileName: "./" + filepath.ToSlash(filepath.Join("_build", p.Name)),
+			SPDXID:   id,
+			Checksums: []checksum{
+				{Algorithm: "SHA256", ChecksumValue: p.SHA256},
+			},
+			LicenseConcluded: "GPL-2.0-only",
+			CopyrightText:    "NOASSERTION",
+		})
+		doc.Relationships = append(doc.Relationships, relationship{id, "PATCH_APPLIED", linuxID})
+	}
+	return doc
+}
+
+// creationTime returns the document’s creation time from SOURCE_DATE_EPOCH
+// (e.g. the commit time, see .github/workflows/pull.yml) instead of the current
+// time, so that the committed SBOM only changes when its inputs change.
+func creationTime() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Time{}, fmt.Errorf("SOURCE_DATE_EPOCH not set (e.g. SOURCE_DATE_EPOCH=$(git log -1 --format=%%ct))")
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("SOURCE_DATE_EPOCH: %v", err)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+func logic(root, output string) error {
+	info, err := buildinfo.Read(root)
+	if err != nil {
+		return err
+	}
+	created, err := creationTime()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(sbom(info, created), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, append(b, '\n'), 0644); err != nil {
+		return err
+	}
+	log.Printf("wrote %s for Linux %s (%d patches)", output, info.Release, len(info.Patches))
+	return nil
+}
+
+func main() {
+	var (
+		root = flag.String("root",
+			".",
+			"path to the kernel repository checkout")
+
+		output = flag.String("output",
+			"kernel.spdx.json",
+			"path to write the SPDX document to")
+	)
+	flag.Parse()
+	if err := logic(*root, *output); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,139 @@
+// Package buildinfo collects information about the kernel build whose
+// artifacts are committed to this repository: the version banner embedded in
+// vmlinuz, the upstream source and the applied patches.
+package buildinfo
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gokrazy/kernel/internal/ikconfig"
+)
+
+// Patch is a patch from _build/series.
+type Patch struct {
+	Name   string `json:"name"` // file name, relative to _build
+	SHA256 string `json:"sha256"`
+}
+
+// Info describes a kernel build.
+type Info struct {
+	Release   string `json:"release"`   // kernel release, e.g. 6.12.9
+	Builder   string `json:"builder"`   // user@host which built the kernel, e.g. gokrazy@docker
+	Toolchain string `json:"toolchain"` // compiler and linker versions
+	Version   string `json:"version"`   // build number and flags, e.g. #1 SMP PREEMPT
+
+	// BannerTimestamp is the date at the end of the banner. Builds are
+	// reproducible, so this is a fixed timestamp, not when the kernel was
+	// built. nil if the banner contains no parseable date.
+	BannerTimestamp *time.Time `json:"banner_timestamp,omitempty"`
+
+	// SourceURL is the upstream tarball, from _build/upstream-url.txt. Its
+	// hash is not recorded: gokr-rebuild-kernel does not persist it. Use the
+	// signed sha256sums.asc next to the tarball on kernel.org to verify it.
+	SourceURL string  `json:"source_url"`
+	Patches   []Patch `json:"patches"` // from _build/series, in application order
+
+	ImageSHA256  string `json:"image_sha256"`  // of vmlinuz
+	ConfigSHA256 string `json:"config_sha256"` // of the embedded .config
+}
+
+// The banner is printed by the kernel on boot (and found in /proc/version).
+// The build number distinguishes linux_banner from other, similar strings.
+var bannerRe = regexp.MustCompile(`Linux version (\S+) \(([^()]*)\) \((.+?)\) (#\d+ [^\n\x00]*)\n`)
+
+// ParseBanner parses the Linux version banner found in image into info.
+func ParseBanner(image []byte, info *Info) error {
+	m := bannerRe.FindSubmatch(image)
+	if m == nil {
+		return errors.New("Linux version banner not found")
+	}
+	info.Release = string(m[1])
+	info.Builder = string(m[2])
+	info.Toolchain = string(m[3])
+	version := strings.Fields(string(m[4]))
+	// The banner ends in the output of date(1), e.g. Wed Mar  1 20:57:29 UTC 2017
+	if len(version) > 6 {
+		date := strings.Join(version[len(version)-6:], " ")
+		if t, err := time.Parse("Mon Jan 2 15:04:05 MST 2006", date); err == nil {
+			info.BannerTimestamp = &t
+			version = version[:len(version)-6]
+		}
+	}
+	info.Version = strings.Join(version, " ")
+	return nil
+}
+
+// ReadSeries returns the patches listed in the quilt-style series file in the
+// build directory, in application order.
+func ReadSeries(buildDir string) ([]Patch, error) {
+	b, err := os.ReadFile(filepath.Join(buildDir, "series"))
+	if err != nil {
+		return nil, err
+	}
+	var patches []Patch
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := strings.Fields(line)[0]
+		contents, err := os.ReadFile(filepath.Join(buildDir, name))
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, Patch{
+			Name:   name,
+			SHA256: SHA256(contents),
+		})
+	}
+	return patches, scanner.Err()
+}
+
+// SHA256 returns the hex-encoded SHA-256 hash of b.
+func SHA256(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// Read collects the build information for the kernel repository checkout at
+// root.
+func Read(root string) (*Info, error) {
+	var info Info
+	fn := filepath.Join(root, "vmlinuz")
+	image, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	info.ImageSHA256 = SHA256(image)
+	if err := ParseBanner(image, &info); err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	config, err := ikconfig.Extract(image)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	info.ConfigSHA256 = SHA256(config)
+
+	buildDir := filepath.Join(root, "_build")
+	url, err := os.ReadFile(filepath.Join(buildDir, "upstream-url.txt"))
+	if err != nil {
+		return nil, err
+	}
+	info.SourceURL = strings.TrimSpace(string(url))
+	info.Patches, err = ReadSeries(buildDir)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,105 @@
+package buildinfo
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+var bannerTimestamp = time.Date(2017, 3, 1, 20, 57, 29, 0, time.UTC)
+
+const toolchain = "aarch64-linux-gnu-gcc (Debian 12.2.0-14) 12.2.0, GNU ld (GNU Binutils for Debian) 2.40"
+
+func TestParseBanner(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		image   string
+		want    Info
+		wantErr bool
+	}{
+		{
+			desc:  "linux_banner",
+			image: "\x00Linux version 6.12.9 (gokrazy@docker) (" + toolchain + ") #1 SMP PREEMPT Wed Mar  1 20:57:29 UTC 2017\n\x00",
+			want: Info{
+				Release:         "6.12.9",
+				Builder:         "gokrazy@docker",
+				Toolchain:       toolchain,
+				Version:         "#1 SMP PREEMPT",
+				BannerTimestamp: &bannerTimestamp,
+			},
+		},
+
+		{
+			// The kernel also contains a variant of the banner without
+			// build number, which must not be mistaken for linux_banner.
+			desc:  "both variants",
+			image: "Linux version 6.12.9 (gokrazy@docker) (" + toolchain + ") # SMP PREEMPT Wed Mar  1 20:57:29 UTC 2017\n\x00Linux version 6.12.9 (gokrazy@docker) (" + toolchain + ") #1 SMP PREEMPT Wed Mar  1 20:57:29 UTC 2017\n\x00",
+			want: Info{
+				Release:         "6.12.9",
+				Builder:         "gokrazy@docker",
+				Toolchain:       toolchain,
+				Version:         "#1 SMP PREEMPT",
+				BannerTimestamp: &bannerTimestamp,
+			},
+		},
+
+		{
+			desc:    "variant without build number",
+			image:   "Linux version 6.12.9 (gokrazy@docker) (" + toolchain + ") # SMP PREEMPT Wed Mar  1 20:57:29 UTC 2017\n\x00",
+			wantErr: true,
+		},
+
+		{
+			desc:  "unparseable date",
+			image: "Linux version 6.12.9 (gokrazy@docker) (" + toolchain + ") #2 SMP\n",
+			want: Info{
+				Release:   "6.12.9",
+				Builder:   "gokrazy@docker",
+				Toolchain: toolchain,
+				Version:   "#2 SMP",
+			},
+		},
+
+		{
+			desc:    "no banner",
+			image:   "hello world\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got Info
+			err := ParseBanner([]byte(tt.image), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseBanner() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Release != tt.want.Release ||
+				got.Builder != tt.want.Builder ||
+				got.Toolchain != tt.want.Toolchain ||
+				got.Version != tt.want.Version ||
+				(got.BannerTimestamp == nil) != (tt.want.BannerTimestamp == nil) ||
+				(got.BannerTimestamp != nil && !got.BannerTimestamp.Equal(*tt.want.BannerTimestamp)) {
+				t.Errorf("ParseBanner() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBannerVmlinuz(t *testing.T) {
+	image, err := os.ReadFile("../../vmlinuz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info Info
+	if err := ParseBanner(image, &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Release == "" || info.Version == "" || info.Toolchain == "" {
+		t.Errorf("ParseBanner(vmlinuz) = %+v, want release, version and toolchain", info)
+	}
+}
@@ -0,0 +1,220 @@
+// Package fdt parses flattened device tree blobs (.dtb files), as specified in
+// https://devicetree-specification.readthedocs.io/en/stable/flattened-format.html
+package fdt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+const magic = 0xd00dfeed
+
+// Structure block tokens.
+const (
+	tokenBeginNode = 0x1
+	tokenEndNode   = 0x2
+	tokenProp      = 0x3
+	tokenNop       = 0x4
+	tokenEnd       = 0x9
+)
+
+// Node is a device tree node.
+type Node struct {
+	Name     string // including the unit address, e.g. spi@7e204000
+	Props    map[string][]byte
+	Children []*Node
+}
+
+// Child returns the direct child with the specified name, or nil.
+func (n *Node) Child(name string) *Node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Lookup returns the node at the specified absolute path (e.g.
+// /soc/spi@7e204000), or nil if there is no such node.
+func (n *Node) Lookup(path string) *Node {
+	cur := n
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		if cur = cur.Child(name); cur == nil {
+			return nil
+		}
+	}
+	return cur
+}
+
+// String returns the value of a string property, without its trailing NUL
+// byte. For string list properties, only the first string is returned.
+func (n *Node) String(prop string) (string, bool) {
+	v, ok := n.Props[prop]
+	if !ok {
+		return "", false
+	}
+	s, _, _ := bytes.Cut(v, []byte{0})
+	return string(s), true
+}
+
+// Strings returns the values of a string list property.
+func (n *Node) Strings(prop string) []string {
+	v, ok := n.Props[prop]
+	if !ok {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(v), "\x00"), "\x00")
+}
+
+type header struct {
+	Magic           uint32
+	TotalSize       uint32
+	OffDtStruct     uint32
+	OffDtStrings    uint32
+	OffMemRsvmap    uint32
+	Version         uint32
+	LastCompVersion uint32
+	BootCpuidPhys   uint32
+	SizeDtStrings   uint32
+	SizeDtStruct    uint32
+}
+
+// Parse parses a flattened device tree blob and returns its root node.
+func Parse(b []byte) (*Node, error) {
+	var hdr header
+	if err := binary.Read(bytes.NewReader(b), binary.BigEndian, &hdr); err != nil {
+		return nil, fmt.Errorf("reading header: %v", err)
+	}
+	if hdr.Magic != magic {
+		return nil, fmt.Errorf("invalid magic: got %#x, want %#x", hdr.Magic, magic)
+	}
+	if int(hdr.TotalSize) > len(b) {
+		return nil, fmt.Errorf("truncated blob: header says %d bytes, got %d", hdr.TotalSize, len(b))
+	}
+	if hdr.LastCompVersion > 17 {
+		return nil, fmt.Errorf("unsupported version %d", hdr.LastCompVersion)
+	}
+	if end := uint64(hdr.OffDtStruct) + uint64(hdr.SizeDtStruct); end > uint64(hdr.TotalSize) {
+		return nil, fmt.Errorf("structure block exceeds blob")
+	}
+	if end := uint64(hdr.OffDtStrings) + uint64(hdr.SizeDtStrings); end > uint64(hdr.TotalSize) {
+		return nil, fmt.Errorf("strings block exceeds blob")
+	}
+	p := parser{
+		structs: b[hdr.OffDtStruct : hdr.OffDtStruct+hdr.SizeDtStruct],
+		strings: b[hdr.OffDtStrings : hdr.OffDtStrings+hdr.SizeDtStrings],
+	}
+	return p.parse()
+}
+
+type parser struct {
+	structs []byte
+	strings []byte
+	off     int
+}
+
+func (p *parser) u32() (uint32, error) {
+	if p.off+4 > len(p.structs) {
+		return 0, fmt.Errorf("unexpected end of structure block at offset %d", p.off)
+	}
+	v := binary.BigEndian.Uint32(p.structs[p.off:])
+	p.off += 4
+	return v, nil
+}
+
+func (p *parser) align() {
+	p.off = (p.off + 3) &^ 3
+}
+
+func (p *parser) cstring(b []byte, off int) (string, error) {
+	if off >= len(b) {
+		return "", fmt.Errorf("string offset %d out of range", off)
+	}
+	idx := bytes.IndexByte(b[off:], 0)
+	if idx == -1 {
+		return "", fmt.Errorf("unterminated string at offset %d", off)
+	}
+	return string(b[off : off+idx]), nil
+}
+
+func (p *parser) parse() (*Node, error) {
+	var (
+		root  *Node
+		stack []*Node
+	)
+	for {
+		token, err := p.u32()
+		if err != nil {
+			return nil, err
+		}
+		switch token {
+		case tokenBeginNode:
+			name, err := p.cstring(p.structs, p.off)
+			if err != nil {
+				return nil, err
+			}
+			p.off += len(name) + 1
+			p.align()
+			n := &Node{Name: name, Props: make(map[string][]byte)}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, fmt.Errorf("multiple root nodes")
+				}
+				root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, n)
+			}
+			stack = append(stack, n)
+
+		case tokenEndNode:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("unbalanced end node at offset %d", p.off-4)
+			}
+			stack = stack[:len(stack)-1]
+
+		case tokenProp:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("property outside of node at offset %d", p.off-4)
+			}
+			length, err := p.u32()
+			if err != nil {
+				return nil, err
+			}
+			nameoff, err := p.u32()
+			if err != nil {
+				return nil, err
+			}
+			if p.off+int(length) > len(p.structs) {
+				return nil, fmt.Errorf("property value exceeds structure block at offset %d", p.off)
+			}
+			name, err := p.cstring(p.strings, int(nameoff))
+			if err != nil {
+				return nil, err
+			}
+			stack[len(stack)-1].Props[name] = p.structs[p.off : p.off+int(length)]
+			p.off += int(length)
+			p.align()
+
+		case tokenNop:
+
+		case tokenEnd:
+			if len(stack) != 0 {
+				return nil, fmt.Errorf("end token inside of node %q", stack[len(stack)-1].Name)
+			}
+			if root == nil {
+				return nil, fmt.Errorf("no root node")
+			}
+			return root, nil
+
+		default:
+			return nil, fmt.Errorf("unknown token %#x at offset %d", token, p.off-4)
+		}
+	}
+}
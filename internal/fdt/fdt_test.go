@@ -0,0 +1,126 @@
+package fdt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	b, err := os.ReadFile("../../bcm2710-rpi-3-b.dtb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const spi = "/soc/spi@7e204000"
+	n := root.Lookup(spi)
+	if n == nil {
+		t.Fatalf("%s: node not found", spi)
+	}
+	if got, _ := n.String("status"); got != "okay" {
+		t.Errorf("%s: status = %q, want %q", spi, got, "okay")
+	}
+	for _, name := range []string{"spidev@0", "spidev@1"} {
+		if n.Child(name) == nil {
+			t.Errorf("%s/%s: node not found", spi, name)
+		}
+	}
+}
+
+// blob assembles a flattened device tree with the specified structure block
+// (a sequence of tokens and their payload) and strings block.
+func blob(structs []uint32, strs string) []byte {
+	const headerSize = 40
+	var structBuf bytes.Buffer
+	for _, v := range structs {
+		binary.Write(&structBuf, binary.BigEndian, v)
+	}
+	hdr := header{
+		Magic:           magic,
+		OffDtStruct:     headerSize,
+		SizeDtStruct:    uint32(structBuf.Len()),
+		OffDtStrings:    headerSize + uint32(structBuf.Len()),
+		SizeDtStrings:   uint32(len(strs)),
+		Version:         17,
+		LastCompVersion: 16,
+	}
+	hdr.TotalSize = hdr.OffDtStrings + hdr.SizeDtStrings
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, hdr)
+	buf.Write(structBuf.Bytes())
+	buf.WriteString(strs)
+	return buf.Bytes()
+}
+
+func TestParseErrors(t *testing.T) {
+	// A root node (whose name is the empty string, padded to 4 bytes) with a
+	// single empty property named "a".
+	valid := blob([]uint32{
+		tokenBeginNode, 0,
+		tokenProp, 0, 0,
+		tokenEndNode,
+		tokenEnd,
+	}, "a\x00")
+	if _, err := Parse(valid); err != nil {
+		t.Fatalf("Parse(valid) = %v", err)
+	}
+
+	badMagic := bytes.Clone(valid)
+	badMagic[0] = 0
+
+	for _, tt := range []struct {
+		desc    string
+		b       []byte
+		wantErr string
+	}{
+		{
+			desc:    "bad magic",
+			b:       badMagic,
+			wantErr: "invalid magic",
+		},
+
+		{
+			desc:    "truncated header",
+			b:       valid[:20],
+			wantErr: "reading header",
+		},
+
+		{
+			desc:    "truncated blob",
+			b:       valid[:len(valid)-4],
+			wantErr: "truncated blob",
+		},
+
+		{
+			desc: "unbalanced end node",
+			b: blob([]uint32{
+				tokenBeginNode, 0,
+				tokenEndNode,
+				tokenEndNode,
+				tokenEnd,
+			}, ""),
+			wantErr: "unbalanced end node",
+		},
+
+		{
+			desc: "missing end node",
+			b: blob([]uint32{
+				tokenBeginNode, 0,
+				tokenEnd,
+			}, ""),
+			wantErr: "end token inside of node",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := Parse(tt.b)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
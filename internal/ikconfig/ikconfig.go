@@ -0,0 +1,137 @@
+// Package ikconfig extracts and parses the kernel configuration which
+// CONFIG_IKCONFIG embeds into the kernel image.
+package ikconfig
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	startMarker = []byte("IKCFG_ST")
+	endMarker   = []byte("IKCFG_ED")
+)
+
+// Extract returns the kernel configuration (in .config format) embedded in
+// the specified kernel image. Both uncompressed images (as found in this
+// repository’s vmlinuz) and gzip-compressed images are supported.
+func Extract(image []byte) ([]byte, error) {
+	if bytes.HasPrefix(image, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(image))
+		if err != nil {
+			return nil, err
+		}
+		image, err = io.ReadAll(zr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	start := bytes.Index(image, startMarker)
+	if start == -1 {
+		return nil, errors.New("IKCFG_ST marker not found (kernel not built with CONFIG_IKCONFIG=y?)")
+	}
+	start += len(startMarker)
+	end := bytes.Index(image[start:], endMarker)
+	if end == -1 {
+		return nil, errors.New("IKCFG_ED marker not found")
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(image[start : start+end]))
+	if err != nil {
+		return nil, fmt.Errorf("embedded config: %v", err)
+	}
+	return io.ReadAll(zr)
+}
+
+// Load returns the kernel configuration contained in b, which can be a kernel
+// image (see Extract), a .config file or a gzip-compressed .config file (like
+// /proc/config.gz).
+func Load(b []byte) ([]byte, error) {
+	if bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		b, err = io.ReadAll(zr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if bytes.Contains(b, startMarker) {
+		return Extract(b)
+	}
+	if !bytes.HasPrefix(b, []byte("#")) && !bytes.HasPrefix(b, []byte("CONFIG_")) {
+		return nil, errors.New("neither a kernel image with embedded config nor a .config file")
+	}
+	return b, nil
+}
+
+// Parse parses a kernel configuration in .config format and returns the value
+// of each option (e.g. CONFIG_TUN → y). Options which are explicitly not set
+// (“# CONFIG_X is not set”) have the value n. Quotes around string values are
+// retained.
+func Parse(r io.Reader) (map[string]string, error) {
+	config := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "# CONFIG_") && strings.HasSuffix(line, " is not set") {
+			name := strings.TrimSuffix(strings.TrimPrefix(line, "# "), " is not set")
+			config[name] = "n"
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed config line: %q", line)
+		}
+		config[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ParseFile parses the kernel configuration (or list of required options) in
+// .config format in the file fn, see Parse.
+func ParseFile(fn string) (map[string]string, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	config, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	return config, nil
+}
+
+// Value returns the value of the specified option, treating options which are
+// absent from the configuration as not set (n), like Kconfig does.
+func Value(config map[string]string, name string) string {
+	if v, ok := config[name]; ok {
+		return v
+	}
+	return "n"
+}
+
+// Satisfied reports whether the option name has the value want in config.
+// want can list alternatives separated by |, e.g. y|m.
+func Satisfied(config map[string]string, name, want string) bool {
+	got := Value(config, name)
+	for _, alt := range strings.Split(want, "|") {
+		if got == alt {
+			return true
+		}
+	}
+	return false
+}
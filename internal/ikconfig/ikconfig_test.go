@@ -0,0 +1,172 @@
+package ikconfig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		config  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			desc:   "values",
+			config: "CONFIG_TUN=y\nCONFIG_VETH=m\nCONFIG_HZ=250\nCONFIG_LOCALVERSION=\"-gokrazy\"\n",
+			want: map[string]string{
+				"CONFIG_TUN":          "y",
+				"CONFIG_VETH":         "m",
+				"CONFIG_HZ":           "250",
+				"CONFIG_LOCALVERSION": `"-gokrazy"`,
+			},
+		},
+
+		{
+			desc:   "not set",
+			config: "# CONFIG_KASAN is not set\n",
+			want:   map[string]string{"CONFIG_KASAN": "n"},
+		},
+
+		{
+			desc:   "comments and blank lines",
+			config: "#\n# Automatically generated file; DO NOT EDIT.\n#\n\n  CONFIG_TUN=y  \n",
+			want:   map[string]string{"CONFIG_TUN": "y"},
+		},
+
+		{
+			desc:   "alternatives",
+			config: "CONFIG_VETH=y|m\n",
+			want:   map[string]string{"CONFIG_VETH": "y|m"},
+		},
+
+		{
+			desc:    "malformed",
+			config:  "CONFIG_TUN\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.config))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSatisfied(t *testing.T) {
+	config := map[string]string{
+		"CONFIG_TUN":   "y",
+		"CONFIG_VETH":  "m",
+		"CONFIG_KASAN": "n",
+	}
+	for _, tt := range []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"CONFIG_TUN", "y", true},
+		{"CONFIG_TUN", "m", false},
+		{"CONFIG_TUN", "y|m", true},
+		{"CONFIG_VETH", "y|m", true},
+		{"CONFIG_VETH", "y", false},
+		{"CONFIG_KASAN", "n", true},
+		{"CONFIG_KASAN", "y|m", false},
+		{"CONFIG_ABSENT", "n", true},
+		{"CONFIG_ABSENT", "y|m", false},
+	} {
+		if got := Satisfied(config, tt.name, tt.want); got != tt.ok {
+			t.Errorf("Satisfied(%s=%s, %s) = %v, want %v", tt.name, Value(config, tt.name), tt.want, got, tt.ok)
+		}
+	}
+}
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	image, err := os.ReadFile("../../vmlinuz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := Extract(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(config, []byte("\nCONFIG_IKCONFIG=y\n")) {
+		t.Fatalf("Extract(vmlinuz) does not contain CONFIG_IKCONFIG=y")
+	}
+
+	for _, tt := range []struct {
+		desc    string
+		b       []byte
+		wantErr bool
+	}{
+		{
+			desc: "image",
+			b:    image,
+		},
+
+		{
+			desc: "gzip-compressed image",
+			b:    gzipped(t, image),
+		},
+
+		{
+			desc: ".config",
+			b:    config,
+		},
+
+		{
+			desc: "gzip-compressed .config",
+			b:    gzipped(t, config),
+		},
+
+		{
+			desc:    "neither",
+			b:       []byte("hello world\n"),
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := Load(tt.b)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Load() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, config) {
+				t.Errorf("Load() returned a different config than Extract(vmlinuz)")
+			}
+		})
+	}
+}
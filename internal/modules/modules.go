@@ -0,0 +1,97 @@
+// Package modules reads the kernel modules tree (lib/modules/<release>) as
+// installed into this repository by gokr-rebuild-kernel.
+package modules
+
+import (
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Dir returns the lib/modules/<release> directory below root. There must be
+// exactly one kernel release in the tree.
+func Dir(root string) (string, error) {
+	base := filepath.Join(root, "lib", "modules")
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return "", err
+	}
+	var releases []string
+	for _, e := range entries {
+		if e.IsDir() {
+			releases = append(releases, e.Name())
+		}
+	}
+	if len(releases) != 1 {
+		return "", fmt.Errorf("%s: expected exactly one kernel release, found %q", base, releases)
+	}
+	return filepath.Join(base, releases[0]), nil
+}
+
+// Name returns the module name for a path as found in modules.dep, e.g.
+// kernel/drivers/net/usb/r8152.ko turns into r8152. Like the kernel, dashes
+// are normalized to underscores.
+func Name(path string) string {
+	name := filepath.Base(path)
+	if idx := strings.Index(name, ".ko"); idx > -1 {
+		name = name[:idx]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// Sizes returns the size of each module file below dir, keyed by its path
+// relative to dir (using forward slashes, like modules.dep).
+func Sizes(dir string) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.Contains(d.Name(), ".ko") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sizes[filepath.ToSlash(rel)] = info.Size()
+		return nil
+	})
+	return sizes, err
+}
+
+// ReadModinfo returns the key/value pairs (e.g. license, firmware, parm) from
+// the .modinfo section of the (uncompressed) kernel module at path. Keys can
+// occur multiple times.
+func ReadModinfo(path string) (map[string][]string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sec := f.Section(".modinfo")
+	if sec == nil {
+		return nil, fmt.Errorf("%s: no .modinfo section", path)
+	}
+	b, err := sec.Data()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	info := make(map[string][]string)
+	for _, entry := range bytes.Split(b, []byte{0}) {
+		key, value, ok := strings.Cut(string(entry), "=")
+		if !ok {
+			continue // padding
+		}
+		info[key] = append(info[key], value)
+	}
+	return info, nil
+}
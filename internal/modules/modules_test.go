@@ -0,0 +1,39 @@
+package modules
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestName(t *testing.T) {
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{"kernel/drivers/net/usb/r8152.ko", "r8152"},
+		{"kernel/crypto/ecdh_generic.ko", "ecdh_generic"},
+		{"kernel/drivers/hid/hid-generic.ko.xz", "hid_generic"},
+	} {
+		if got := Name(tt.path); got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestReadModinfo(t *testing.T) {
+	dir, err := Dir("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := ReadModinfo(filepath.Join(dir, "kernel", "net", "bluetooth", "bluetooth.ko"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := info["license"], []string{"GPL"}; !slices.Equal(got, want) {
+		t.Errorf("license = %q, want %q", got, want)
+	}
+	if got := info["parm"]; len(got) == 0 {
+		t.Errorf("parm = %q, want at least one module parameter", got)
+	}
+}